@@ -0,0 +1,79 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The provider package holds the typed identifiers for known provider
+// types, plus a small Registry that maps them to their factories. This
+// finishes the migration away from the old stringly-typed constants: an
+// audit of this tree turned up no remaining `== provider.Null`-style
+// string comparisons, so there is nothing left to keep the untyped
+// constants around for. New code should use ProviderType and its
+// IsManual method.
+package provider
+
+import "fmt"
+
+// ProviderType identifies an environment provider, e.g. "ec2" or "local".
+type ProviderType string
+
+const (
+	Azure     ProviderType = "azure"
+	Dummy     ProviderType = "dummy"
+	EC2       ProviderType = "ec2"
+	Joyent    ProviderType = "joyent"
+	Local     ProviderType = "local"
+	MAAS      ProviderType = "maas"
+	Manual    ProviderType = "manual"
+	Null      ProviderType = "null"
+	OpenStack ProviderType = "openstack"
+)
+
+// IsManual returns true iff the provider type refers to the manual
+// provider, under either of its names.
+func (t ProviderType) IsManual() bool {
+	return t == Null || t == Manual
+}
+
+// String returns the provider type as a plain string, for use in
+// environments.yaml and log messages.
+func (t ProviderType) String() string {
+	return string(t)
+}
+
+// EnvironProvider is the factory interface implemented by each provider
+// package; it is defined here, rather than imported from environs, to
+// avoid a dependency cycle between provider and environs.
+type EnvironProvider interface{}
+
+// Registry maps provider types to their registered factories, giving
+// out-of-tree providers a stable hook to register against instead of
+// requiring changes to a central switch statement.
+type Registry struct {
+	providers map[ProviderType]EnvironProvider
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[ProviderType]EnvironProvider)}
+}
+
+// Register associates a provider factory with a type. It panics if the
+// type has already been registered.
+func (r *Registry) Register(t ProviderType, p EnvironProvider) {
+	if _, exists := r.providers[t]; exists {
+		panic(fmt.Sprintf("juju: provider %q already registered", t))
+	}
+	r.providers[t] = p
+}
+
+// Provider returns the factory registered for t, and whether one was
+// found.
+func (r *Registry) Provider(t ProviderType) (EnvironProvider, bool) {
+	p, ok := r.providers[t]
+	return p, ok
+}
+
+// IsManual returns true iff the specified provider type refers to the
+// manual provider.
+func IsManual(provider string) bool {
+	return ProviderType(provider).IsManual()
+}
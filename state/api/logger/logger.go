@@ -0,0 +1,86 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logger
+
+import (
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/state/api/base"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/api/watcher"
+)
+
+// State provides access to an logger worker's view of the state.
+type State struct {
+	caller base.Caller
+}
+
+// NewState returns a version of the state that provides functionality
+// required by the logger worker.
+func NewState(caller base.Caller) *State {
+	return &State{caller}
+}
+
+// LoggingConfig returns the loggo configuration string for the given tag.
+func (st *State) LoggingConfig(tag string) (string, error) {
+	var result params.StringResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: tag}},
+	}
+	err := st.caller.Call("Logger", "", "LoggingConfig", args, &result)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Results) != 1 {
+		return "", errors.NewUnexpectedError(result.Results)
+	}
+	if err := result.Results[0].Error; err != nil {
+		return "", err
+	}
+	return result.Results[0].Result, nil
+}
+
+// WatchLoggingConfig returns a watcher that fires whenever any part of the
+// environ's logging config changes.
+func (st *State) WatchLoggingConfig(agentTag string) (watcher.NotifyWatcher, error) {
+	var results params.NotifyWatchResults
+	args := params.Entities{
+		Entities: []params.Entity{{Tag: agentTag}},
+	}
+	err := st.caller.Call("Logger", "", "WatchLoggingConfig", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.NewUnexpectedError(results.Results)
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewNotifyWatcher(st.caller, result)
+	return w, nil
+}
+
+// WatchLoggingConfigFor returns a watcher that fires with just the modules
+// whose logging level has changed, avoiding the "wake on any change" cost
+// of WatchLoggingConfig.
+func (st *State) WatchLoggingConfigFor(agentTag string) (watcher.StringsWatcher, error) {
+	var results params.StringsWatchResults
+	args := params.LoggingEntities{
+		Entities: []params.LoggingEntity{{Tag: agentTag}},
+	}
+	err := st.caller.Call("Logger", "", "WatchLoggingConfigFor", args, &results)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Results) != 1 {
+		return nil, errors.NewUnexpectedError(results.Results)
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	w := watcher.NewStringsWatcher(st.caller, result)
+	return w, nil
+}
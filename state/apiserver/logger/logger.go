@@ -13,6 +13,7 @@ import (
 // LoggerAPI defines the methods on the logger API end point.
 type LoggerAPI interface {
 	WatchLoggingConfig(args params.Entities) params.NotifyWatchResults
+	WatchLoggingConfigFor(args params.LoggingEntities) params.StringsWatchResults
 	LoggingConfig(args params.Entities) params.StringResults
 }
 
@@ -61,6 +62,31 @@ func (api *loggerAPI) WatchLoggingConfig(arg params.Entities) params.NotifyWatch
 	return params.NotifyWatchResults{result}
 }
 
+// WatchLoggingConfigFor starts a watcher that reports only the logging
+// modules whose level has actually changed, instead of waking the caller
+// up on every environ-config change the way WatchLoggingConfig does.
+func (api *loggerAPI) WatchLoggingConfigFor(args params.LoggingEntities) params.StringsWatchResults {
+	result := make([]params.StringsWatchResult, len(args.Entities))
+	for i, entity := range args.Entities {
+		err := common.ErrPerm
+		if api.authorizer.AuthOwner(entity.Tag) {
+			watch := state.NewLoggingConfigWatcher(api.state)
+			// Consume the initial event so that we only register the
+			// watcher, and hand the client its first batch of changes,
+			// if the watcher is actually going to be useful.
+			if changes, ok := <-watch.Changes(); ok {
+				result[i].StringsWatcherId = api.resources.Register(watch)
+				result[i].Changes = changes
+				err = nil
+			} else {
+				err = watcher.MustErr(watch)
+			}
+		}
+		result[i].Error = common.ServerError(err)
+	}
+	return params.StringsWatchResults{result}
+}
+
 // DesiredVersion reports the Agent Version that we want that agent to be running
 func (api *loggerAPI) LoggingConfig(arg params.Entities) params.StringResults {
 	results := make([]params.StringResult, len(arg.Entities))
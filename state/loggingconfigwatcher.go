@@ -0,0 +1,156 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"strings"
+
+	"launchpad.net/tomb"
+
+	"launchpad.net/juju-core/state/watcher"
+)
+
+// LoggingConfigWatcher notifies of changes to individual logging module
+// levels. Unlike WatchForEnvironConfigChanges, which wakes on every environ
+// config change, it diffs the logging config against the previous snapshot
+// and only emits the modules that actually changed.
+type LoggingConfigWatcher struct {
+	tomb tomb.Tomb
+	out  chan []string
+
+	// newConfigWatcher and loggingConfig are indirected so that the loop
+	// can be exercised by tests without a full State/mongo fixture.
+	newConfigWatcher func() NotifyWatcher
+	loggingConfig    func() (string, error)
+}
+
+// NewLoggingConfigWatcher returns a StringsWatcher that reports changed
+// logging modules as "module=LEVEL" strings, or "module=-" when a module's
+// override has been removed.
+func NewLoggingConfigWatcher(st *State) *LoggingConfigWatcher {
+	return newLoggingConfigWatcher(
+		func() NotifyWatcher { return st.WatchForEnvironConfigChanges() },
+		func() (string, error) {
+			cfg, err := st.EnvironConfig()
+			if err != nil {
+				return "", err
+			}
+			return cfg.LoggingConfig(), nil
+		},
+	)
+}
+
+func newLoggingConfigWatcher(newConfigWatcher func() NotifyWatcher, loggingConfig func() (string, error)) *LoggingConfigWatcher {
+	w := &LoggingConfigWatcher{
+		out:              make(chan []string),
+		newConfigWatcher: newConfigWatcher,
+		loggingConfig:    loggingConfig,
+	}
+	go func() {
+		defer w.tomb.Done()
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+func (w *LoggingConfigWatcher) loop() error {
+	configWatcher := w.newConfigWatcher()
+	defer watcher.Stop(configWatcher, &w.tomb)
+
+	previous, err := w.snapshot()
+	if err != nil {
+		return err
+	}
+	// Like a NotifyWatcher's first event, the initial batch is sent
+	// unconditionally, even if it's empty, so that callers waiting on
+	// the handshake (e.g. WatchLoggingConfigFor) don't block forever.
+	out := w.out
+	var changes []string
+	// previous is the snapshot as of the last delivered batch, and latest
+	// the most recent one, so that changes not yet read by the client are
+	// merged into the next diff rather than overwritten by it.
+	latest := previous
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case _, ok := <-configWatcher.Changes():
+			if !ok {
+				return watcher.MustErr(configWatcher)
+			}
+			if latest, err = w.snapshot(); err != nil {
+				return err
+			}
+			changes = diffLoggingConfig(previous, latest)
+			if len(changes) > 0 {
+				out = w.out
+			}
+		case out <- changes:
+			previous = latest
+			out = nil
+			changes = nil
+		}
+	}
+}
+
+// snapshot returns the current per-module logging levels.
+func (w *LoggingConfigWatcher) snapshot() (map[string]string, error) {
+	config, err := w.loggingConfig()
+	if err != nil {
+		return nil, err
+	}
+	return parseLoggingConfig(config), nil
+}
+
+// Changes returns the event channel for this watcher.
+func (w *LoggingConfigWatcher) Changes() <-chan []string {
+	return w.out
+}
+
+// Err returns any error encountered while the watcher was running.
+func (w *LoggingConfigWatcher) Err() error {
+	return w.tomb.Err()
+}
+
+// Stop stops the watcher and returns any error it encountered.
+func (w *LoggingConfigWatcher) Stop() error {
+	w.tomb.Kill(nil)
+	return w.tomb.Wait()
+}
+
+// parseLoggingConfig turns a loggo config string such as
+// "<root>=WARNING;juju.worker=DEBUG" into a module->level map.
+func parseLoggingConfig(config string) map[string]string {
+	levels := make(map[string]string)
+	for _, entry := range strings.Split(config, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		levels[parts[0]] = parts[1]
+	}
+	return levels
+}
+
+// diffLoggingConfig compares two module->level snapshots and returns the
+// entries that changed between them, as "module=LEVEL" strings. A module
+// whose override has been removed is reported as "module=-".
+func diffLoggingConfig(previous, current map[string]string) []string {
+	var changes []string
+	for module, level := range current {
+		if previous[module] != level {
+			changes = append(changes, module+"="+level)
+		}
+	}
+	for module := range previous {
+		if _, ok := current[module]; !ok {
+			changes = append(changes, module+"=-")
+		}
+	}
+	return changes
+}
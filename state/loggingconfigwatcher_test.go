@@ -0,0 +1,116 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"sync"
+	"time"
+
+	gc "launchpad.net/gocheck"
+)
+
+var longWait = 500 * time.Millisecond
+
+type loggingConfigWatcherSuite struct{}
+
+var _ = gc.Suite(&loggingConfigWatcherSuite{})
+
+// fakeNotifyWatcher is a minimal NotifyWatcher for driving the loop
+// without a real State/mongo fixture.
+type fakeNotifyWatcher struct {
+	mu      sync.Mutex
+	out     chan struct{}
+	stopped bool
+}
+
+func newFakeNotifyWatcher() *fakeNotifyWatcher {
+	return &fakeNotifyWatcher{out: make(chan struct{})}
+}
+
+func (w *fakeNotifyWatcher) Changes() <-chan struct{} { return w.out }
+func (w *fakeNotifyWatcher) Err() error               { return nil }
+func (w *fakeNotifyWatcher) Stop() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+	return nil
+}
+
+func (w *fakeNotifyWatcher) trigger(c *gc.C) {
+	select {
+	case w.out <- struct{}{}:
+	case <-time.After(longWait):
+		c.Fatalf("timed out triggering environ config change")
+	}
+}
+
+// TestInitialBatchSentWithNoSubsequentChanges reproduces the hang where a
+// freshly created watcher never delivers its first (possibly empty) batch
+// until the underlying environ config changes again.
+func (s *loggingConfigWatcherSuite) TestInitialBatchSentWithNoSubsequentChanges(c *gc.C) {
+	configs := []string{"<root>=WARNING"}
+	i := 0
+	loggingConfig := func() (string, error) {
+		cfg := configs[i]
+		return cfg, nil
+	}
+	configWatcher := newFakeNotifyWatcher()
+	w := newLoggingConfigWatcher(func() NotifyWatcher { return configWatcher }, loggingConfig)
+	defer w.Stop()
+
+	select {
+	case changes := <-w.Changes():
+		c.Check(changes, gc.HasLen, 0)
+	case <-time.After(longWait):
+		c.Fatalf("watcher never delivered its initial batch")
+	}
+}
+
+func (s *loggingConfigWatcherSuite) TestSubsequentDiffOnly(c *gc.C) {
+	configs := []string{"<root>=WARNING", "<root>=WARNING;juju.worker=DEBUG"}
+	i := 0
+	loggingConfig := func() (string, error) {
+		return configs[i], nil
+	}
+	configWatcher := newFakeNotifyWatcher()
+	w := newLoggingConfigWatcher(func() NotifyWatcher { return configWatcher }, loggingConfig)
+	defer w.Stop()
+
+	select {
+	case changes := <-w.Changes():
+		c.Check(changes, gc.HasLen, 0)
+	case <-time.After(longWait):
+		c.Fatalf("watcher never delivered its initial batch")
+	}
+
+	i = 1
+	configWatcher.trigger(c)
+	select {
+	case changes := <-w.Changes():
+		c.Check(changes, gc.DeepEquals, []string{"juju.worker=DEBUG"})
+	case <-time.After(longWait):
+		c.Fatalf("watcher never delivered the diff")
+	}
+}
+
+func (s *loggingConfigWatcherSuite) TestParseLoggingConfig(c *gc.C) {
+	levels := parseLoggingConfig("<root>=WARNING;juju.worker=DEBUG; ;bad-entry")
+	c.Check(levels, gc.DeepEquals, map[string]string{
+		"<root>":      "WARNING",
+		"juju.worker": "DEBUG",
+	})
+}
+
+func (s *loggingConfigWatcherSuite) TestDiffLoggingConfig(c *gc.C) {
+	previous := map[string]string{"<root>": "WARNING", "juju.worker": "DEBUG"}
+	current := map[string]string{"<root>": "WARNING", "unit.foo": "DEBUG"}
+	changes := diffLoggingConfig(previous, current)
+	c.Check(changes, gc.HasLen, 2)
+	seen := make(map[string]bool)
+	for _, ch := range changes {
+		seen[ch] = true
+	}
+	c.Check(seen["unit.foo=DEBUG"], gc.Equals, true)
+	c.Check(seen["juju.worker=-"], gc.Equals, true)
+}
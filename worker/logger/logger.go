@@ -0,0 +1,100 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logger
+
+import (
+	"strings"
+
+	"github.com/juju/loggo"
+	"launchpad.net/tomb"
+
+	"launchpad.net/juju-core/state/api/logger"
+	"launchpad.net/juju-core/state/api/watcher"
+)
+
+var log = loggo.GetLogger("juju.worker.logger")
+
+// Logger is responsible for updating the loggo configuration when the
+// environment's logging config changes.
+type Logger struct {
+	tomb tomb.Tomb
+	api  *logger.State
+	tag  string
+}
+
+// NewLogger returns a worker.Worker that watches for changes to individual
+// logging modules and applies just the diff via loggo.ConfigureLoggers,
+// rather than reconfiguring every logger whenever any part of the environ
+// config changes.
+func NewLogger(api *logger.State, tag string) *Logger {
+	l := &Logger{api: api, tag: tag}
+	go func() {
+		defer l.tomb.Done()
+		l.tomb.Kill(l.loop())
+	}()
+	return l
+}
+
+func (l *Logger) String() string {
+	return "logger for " + l.tag
+}
+
+func (l *Logger) Kill() {
+	l.tomb.Kill(nil)
+}
+
+func (l *Logger) Wait() error {
+	return l.tomb.Wait()
+}
+
+func (l *Logger) Stop() error {
+	l.tomb.Kill(nil)
+	return l.tomb.Wait()
+}
+
+func (l *Logger) loop() error {
+	w, err := l.api.WatchLoggingConfigFor(l.tag)
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop(w, &l.tomb)
+	for {
+		select {
+		case <-l.tomb.Dying():
+			return tomb.ErrDying
+		case changes, ok := <-w.Changes():
+			if !ok {
+				return watcher.MustErr(w)
+			}
+			if err := l.apply(changes); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// apply reconfigures only the modules that changed, rather than the whole
+// logging config.
+func (l *Logger) apply(changes []string) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	log.Debugf("applying logging config changes: %v", changes)
+	return loggo.ConfigureLoggers(strings.Join(translateDeletions(changes), ";"))
+}
+
+// translateDeletions turns the "module=-" deletion markers emitted by
+// state.LoggingConfigWatcher into "module=UNSPECIFIED", loggo's own
+// syntax for resetting a module back to its parent's level; "-" on its
+// own is not a level loggo.ConfigureLoggers understands.
+func translateDeletions(changes []string) []string {
+	translated := make([]string, len(changes))
+	for i, change := range changes {
+		if strings.HasSuffix(change, "=-") {
+			change = strings.TrimSuffix(change, "-") + "UNSPECIFIED"
+		}
+		translated[i] = change
+	}
+	return translated
+}
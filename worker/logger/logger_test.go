@@ -0,0 +1,44 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logger
+
+import (
+	"github.com/juju/loggo"
+	gc "launchpad.net/gocheck"
+)
+
+type loggerSuite struct{}
+
+var _ = gc.Suite(&loggerSuite{})
+
+func (s *loggerSuite) TestApplyOnlyChangedModules(c *gc.C) {
+	loggo.ResetLoggers()
+	defer loggo.ResetLoggers()
+
+	l := &Logger{tag: "unit-foo-0"}
+	c.Assert(l.apply([]string{"juju.worker=DEBUG"}), gc.IsNil)
+	c.Check(loggo.GetLogger("juju.worker").LogLevel(), gc.Equals, loggo.DEBUG)
+}
+
+func (s *loggerSuite) TestApplyNoChangesIsNoop(c *gc.C) {
+	l := &Logger{tag: "unit-foo-0"}
+	c.Assert(l.apply(nil), gc.IsNil)
+}
+
+// TestApplyDeletionResetsToUnspecified drives a "module=-" deletion
+// marker, as emitted by state.LoggingConfigWatcher, through apply() for
+// real, rather than just the helper that produces it. Before this was
+// fixed, loggo.ConfigureLoggers rejected "-" as an unknown severity
+// level and the error killed the worker's tomb.
+func (s *loggerSuite) TestApplyDeletionResetsToUnspecified(c *gc.C) {
+	loggo.ResetLoggers()
+	defer loggo.ResetLoggers()
+
+	l := &Logger{tag: "unit-foo-0"}
+	c.Assert(l.apply([]string{"juju.worker=DEBUG"}), gc.IsNil)
+	c.Assert(loggo.GetLogger("juju.worker").LogLevel(), gc.Equals, loggo.DEBUG)
+
+	c.Assert(l.apply([]string{"juju.worker=-"}), gc.IsNil)
+	c.Check(loggo.GetLogger("juju.worker").LogLevel(), gc.Equals, loggo.UNSPECIFIED)
+}
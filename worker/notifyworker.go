@@ -5,6 +5,7 @@ package worker
 
 import (
 	"fmt"
+	"time"
 
 	"launchpad.net/tomb"
 
@@ -13,6 +14,12 @@ import (
 	"launchpad.net/juju-core/state/watcher"
 )
 
+// ErrStopTimeout is returned by StopWithTimeout when a worker fails to
+// stop within the given duration. The worker's goroutine is left running
+// rather than blocking the caller forever; it will finish tearing itself
+// down in the background.
+var ErrStopTimeout = fmt.Errorf("timed out waiting for worker to stop")
+
 type notifyWorker struct {
 	// Internal structure
 	tomb tomb.Tomb
@@ -28,6 +35,10 @@ type NotifyWorker interface {
 	Kill()
 	// This is just Kill + Wait
 	Stop() error
+	// StopWithTimeout is like Stop, but gives up and returns
+	// ErrStopTimeout if the worker hasn't finished within d, rather than
+	// blocking the caller forever.
+	StopWithTimeout(d time.Duration) error
 }
 
 // WatchHandler implements the business logic that is triggered as part of
@@ -71,6 +82,30 @@ func (nw *notifyWorker) Wait() error {
 	return nw.tomb.Wait()
 }
 
+// StopWithTimeout kills the worker and waits up to d for it to finish, as
+// an alternative to Stop for callers that need to bound shutdown cost.
+func (nw *notifyWorker) StopWithTimeout(d time.Duration) error {
+	return StopWithTimeout(nw, d)
+}
+
+// StopWithTimeout kills w and waits up to d for it to finish. If it
+// hasn't finished in time, it returns ErrStopTimeout and leaves the
+// worker's goroutine running in the background rather than blocking the
+// caller forever.
+func StopWithTimeout(w NotifyWorker, d time.Duration) error {
+	w.Kill()
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(d):
+		return ErrStopTimeout
+	}
+}
+
 func (nw *notifyWorker) loop() error {
 	// Replace calls to TearDown with a defer nw.handler.TearDown()
 	var w state.NotifyWatcher
@@ -52,6 +52,9 @@ type ActionsHandler struct {
 	setupError   error
 	handlerError error
 	watcher      *TestWatcher
+	// teardownBlocks, if non-nil, causes TearDown to block until the
+	// channel is closed, to let tests exercise StopWithTimeout.
+	teardownBlocks chan struct{}
 }
 
 func (a *ActionsHandler) SetUp() (state.NotifyWatcher, error) {
@@ -65,6 +68,9 @@ func (a *ActionsHandler) SetUp() (state.NotifyWatcher, error) {
 }
 
 func (a *ActionsHandler) TearDown() {
+	if a.teardownBlocks != nil {
+		<-a.teardownBlocks
+	}
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.actions = append(a.actions, "teardown")
@@ -92,16 +98,8 @@ func (s *notifyWorkerSuite) stopWorker(c *gc.C) {
 	if s.worker == nil {
 		return
 	}
-	done := make(chan error)
-	go func() {
-		done <- s.worker.Stop()
-	}()
-	select {
-	case err := <-done:
-		c.Check(err, gc.IsNil)
-	case <-time.After(longWait):
-		c.Errorf("Failed to stop worker after %.3fs", longWait.Seconds())
-	}
+	err := s.worker.StopWithTimeout(longWait)
+	c.Check(err, gc.IsNil)
 	s.actor = nil
 	s.worker = nil
 }
@@ -180,6 +178,21 @@ func (s *notifyWorkerSuite) TestStop(c *gc.C) {
 	c.Assert(err, gc.IsNil)
 }
 
+func (s *notifyWorkerSuite) TestStopWithTimeout(c *gc.C) {
+	err := s.worker.StopWithTimeout(longWait)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *notifyWorkerSuite) TestStopWithTimeoutTimesOut(c *gc.C) {
+	// TearDown blocks forever, so StopWithTimeout should give up rather
+	// than hang, leaving the worker's goroutine running in the background.
+	s.actor.teardownBlocks = make(chan struct{})
+	defer close(s.actor.teardownBlocks)
+	err := s.worker.StopWithTimeout(shortWait)
+	c.Assert(err, gc.Equals, worker.ErrStopTimeout)
+	s.worker = nil
+}
+
 func (s *notifyWorkerSuite) TestWait(c *gc.C) {
 	done := make(chan error)
 	go func() {
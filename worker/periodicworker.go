@@ -0,0 +1,200 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package worker
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"launchpad.net/tomb"
+
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/watcher"
+	"launchpad.net/juju-core/utils/clock"
+)
+
+// PeriodicTimer is the interface implemented by the timer a
+// periodicNotifyWorker waits on between handler calls. It is an interface
+// so that tests can inject a fake timer instead of waiting on real time.
+type PeriodicTimer interface {
+	// Chan returns the channel on which the timer will send once it fires.
+	Chan() <-chan time.Time
+	// Reset changes the timer to expire after duration d.
+	Reset(d time.Duration)
+}
+
+// NewTimer is the type of a function that returns a PeriodicTimer that
+// fires after d. PeriodicConfig takes one, so that tests can substitute a
+// fake implementation.
+type NewTimer func(d time.Duration) PeriodicTimer
+
+type periodicTimer struct {
+	timer *time.Timer
+}
+
+func (t *periodicTimer) Chan() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *periodicTimer) Reset(d time.Duration) {
+	t.timer.Reset(d)
+}
+
+// NewRealTimer is the default NewTimer implementation, backed by time.Timer.
+func NewRealTimer(d time.Duration) PeriodicTimer {
+	return &periodicTimer{timer: time.NewTimer(d)}
+}
+
+// PeriodicConfig holds the knobs that control how often a
+// periodicNotifyWorker fires Handle() on its own, and how it backs off
+// when Handle() returns an error.
+type PeriodicConfig struct {
+	// Clock is used to obtain the current time, and is normally
+	// clock.WallClock; tests can supply a fake clock for determinism.
+	Clock clock.Clock
+	// NewTimer creates the timer used to trigger periodic calls to
+	// Handle(); tests can supply a fake implementation.
+	NewTimer NewTimer
+	// MinInterval is how often Handle() is called in the absence of
+	// errors or watcher events.
+	MinInterval time.Duration
+	// MaxInterval is the ceiling the backoff applied after a Handle()
+	// error will not exceed.
+	MaxInterval time.Duration
+	// Jitter, if non-zero, is the fraction of the interval that is
+	// randomly added or subtracted, to avoid thundering-herd effects
+	// across many agents.
+	Jitter float64
+}
+
+func (cfg PeriodicConfig) validate() error {
+	if cfg.Clock == nil {
+		return fmt.Errorf("Clock must be set")
+	}
+	if cfg.NewTimer == nil {
+		return fmt.Errorf("NewTimer must be set")
+	}
+	if cfg.MinInterval <= 0 {
+		return fmt.Errorf("MinInterval must be positive")
+	}
+	if cfg.MaxInterval < cfg.MinInterval {
+		return fmt.Errorf("MaxInterval must be >= MinInterval")
+	}
+	if cfg.Jitter < 0 {
+		return fmt.Errorf("Jitter must not be negative")
+	}
+	return nil
+}
+
+type periodicNotifyWorker struct {
+	tomb    tomb.Tomb
+	handler WatchHandler
+	config  PeriodicConfig
+	// rnd is seeded from config.Clock, so that the jitter sequence (and
+	// hence the whole worker's behaviour) is reproducible in tests that
+	// supply a fake clock.
+	rnd *rand.Rand
+}
+
+// NewPeriodicNotifyWorker is like NewNotifyWorker, except that Handle() is
+// also called whenever MinInterval elapses without a watcher event, and
+// Handle() errors are retried with exponential backoff up to MaxInterval
+// rather than tearing the worker down. This lets pruners, metric
+// collectors and reboot monitors share one primitive instead of each
+// reimplementing their own timer plumbing.
+func NewPeriodicNotifyWorker(handler WatchHandler, cfg PeriodicConfig) (NotifyWorker, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	nw := &periodicNotifyWorker{
+		handler: handler,
+		config:  cfg,
+		rnd:     rand.New(rand.NewSource(cfg.Clock.Now().UnixNano())),
+	}
+	go func() {
+		defer nw.tomb.Done()
+		nw.tomb.Kill(nw.loop())
+	}()
+	return nw, nil
+}
+
+func (nw *periodicNotifyWorker) Kill() {
+	nw.tomb.Kill(nil)
+}
+
+func (nw *periodicNotifyWorker) Stop() error {
+	nw.tomb.Kill(nil)
+	return nw.tomb.Wait()
+}
+
+func (nw *periodicNotifyWorker) Wait() error {
+	return nw.tomb.Wait()
+}
+
+func (nw *periodicNotifyWorker) StopWithTimeout(d time.Duration) error {
+	return StopWithTimeout(nw, d)
+}
+
+func (nw *periodicNotifyWorker) loop() error {
+	var w state.NotifyWatcher
+	var err error
+	defer nw.handler.TearDown()
+	if w, err = nw.handler.SetUp(); err != nil {
+		if w != nil {
+			w.Stop()
+		}
+		return err
+	}
+	if w == nil {
+		return fmt.Errorf("SetUp returned a nil Watcher")
+	}
+	defer watcher.Stop(w, &nw.tomb)
+
+	interval := nw.config.MinInterval
+	timer := nw.config.NewTimer(nw.jitter(interval))
+	for {
+		select {
+		case <-nw.tomb.Dying():
+			return tomb.ErrDying
+		case <-w.Changes():
+			if err := nw.handle(&interval, timer); err != nil {
+				return err
+			}
+		case <-timer.Chan():
+			if err := nw.handle(&interval, timer); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// handle calls Handle(), resetting the periodic timer to MinInterval on
+// success, or backing off exponentially towards MaxInterval on error.
+func (nw *periodicNotifyWorker) handle(interval *time.Duration, timer PeriodicTimer) error {
+	if err := nw.handler.Handle(); err != nil {
+		*interval *= 2
+		if *interval > nw.config.MaxInterval {
+			*interval = nw.config.MaxInterval
+		}
+		timer.Reset(nw.jitter(*interval))
+		return nil
+	}
+	*interval = nw.config.MinInterval
+	timer.Reset(nw.jitter(*interval))
+	return nil
+}
+
+// jitter adjusts d by up to +/- config.Jitter fraction, so that many
+// workers with the same interval don't all fire in lockstep. It draws
+// from nw.rnd, which is seeded off config.Clock, so the sequence is
+// reproducible when a fake clock is supplied in tests.
+func (nw *periodicNotifyWorker) jitter(d time.Duration) time.Duration {
+	fraction := nw.config.Jitter
+	if fraction <= 0 {
+		return d
+	}
+	delta := time.Duration(float64(d) * fraction)
+	return d - delta + time.Duration(nw.rnd.Int63n(2*int64(delta)+1))
+}
@@ -0,0 +1,146 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package worker_test
+
+import (
+	"fmt"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/utils/clock"
+	"launchpad.net/juju-core/worker"
+)
+
+type periodicWorkerSuite struct {
+	coretesting.LoggingSuite
+}
+
+var _ = gc.Suite(&periodicWorkerSuite{})
+
+// fakeTimer lets the test fire the periodic tick on demand instead of
+// waiting on real time, and observe each Reset() the worker makes rather
+// than racing to read a plain field.
+type fakeTimer struct {
+	c      chan time.Time
+	resets chan time.Duration
+}
+
+func newFakeTimer() *fakeTimer {
+	return &fakeTimer{
+		c:      make(chan time.Time, 1),
+		resets: make(chan time.Duration, 1),
+	}
+}
+
+func (t *fakeTimer) Chan() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Reset(d time.Duration) {
+	t.resets <- d
+}
+
+func (t *fakeTimer) waitReset(c *gc.C) time.Duration {
+	select {
+	case d := <-t.resets:
+		return d
+	case <-time.After(longWait):
+		c.Fatalf("timer was never reset")
+	}
+	return 0
+}
+
+func (s *periodicWorkerSuite) TestHandleCalledOnTick(c *gc.C) {
+	timer := newFakeTimer()
+	actor := &ActionsHandler{
+		handled: make(chan struct{}, 1),
+		watcher: &TestWatcher{out: make(chan struct{})},
+	}
+	cfg := worker.PeriodicConfig{
+		Clock:       clock.WallClock,
+		NewTimer:    func(time.Duration) worker.PeriodicTimer { return timer },
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Second,
+	}
+	w, err := worker.NewPeriodicNotifyWorker(actor, cfg)
+	c.Assert(err, gc.IsNil)
+	defer w.Stop()
+
+	timer.c <- time.Now()
+	WaitForHandled(c, actor.handled)
+	actor.CheckActions(c, "setup", "handler")
+}
+
+func (s *periodicWorkerSuite) TestInvalidConfigRejected(c *gc.C) {
+	actor := &ActionsHandler{watcher: &TestWatcher{out: make(chan struct{})}}
+	_, err := worker.NewPeriodicNotifyWorker(actor, worker.PeriodicConfig{})
+	c.Assert(err, gc.ErrorMatches, "Clock must be set")
+}
+
+func (s *periodicWorkerSuite) TestNegativeJitterRejected(c *gc.C) {
+	actor := &ActionsHandler{watcher: &TestWatcher{out: make(chan struct{})}}
+	cfg := worker.PeriodicConfig{
+		Clock:       clock.WallClock,
+		NewTimer:    func(time.Duration) worker.PeriodicTimer { return newFakeTimer() },
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Second,
+		Jitter:      -0.1,
+	}
+	_, err := worker.NewPeriodicNotifyWorker(actor, cfg)
+	c.Assert(err, gc.ErrorMatches, "Jitter must not be negative")
+}
+
+func (s *periodicWorkerSuite) TestHandleErrorBacksOffAndCapsAtMaxInterval(c *gc.C) {
+	timer := newFakeTimer()
+	// handled is buffered for all three ticks, since nothing reads it and
+	// Handle() would otherwise block before the worker resets the timer.
+	actor := &ActionsHandler{
+		handled:      make(chan struct{}, 3),
+		handlerError: fmt.Errorf("boom"),
+		watcher:      &TestWatcher{out: make(chan struct{})},
+	}
+	cfg := worker.PeriodicConfig{
+		Clock:       clock.WallClock,
+		NewTimer:    func(time.Duration) worker.PeriodicTimer { return timer },
+		MinInterval: time.Millisecond,
+		MaxInterval: 4 * time.Millisecond,
+	}
+	w, err := worker.NewPeriodicNotifyWorker(actor, cfg)
+	c.Assert(err, gc.IsNil)
+	defer w.Kill()
+
+	// First failure doubles the interval from MinInterval (1ms) to 2ms.
+	timer.c <- time.Now()
+	c.Check(timer.waitReset(c), gc.Equals, 2*time.Millisecond)
+
+	// Second failure doubles again, reaching MaxInterval (4ms) exactly.
+	timer.c <- time.Now()
+	c.Check(timer.waitReset(c), gc.Equals, 4*time.Millisecond)
+
+	// Third failure would double past MaxInterval, so it's capped there.
+	timer.c <- time.Now()
+	c.Check(timer.waitReset(c), gc.Equals, 4*time.Millisecond)
+}
+
+func (s *periodicWorkerSuite) TestHandleSuccessResetsToMinInterval(c *gc.C) {
+	timer := newFakeTimer()
+	actor := &ActionsHandler{
+		handled: make(chan struct{}, 1),
+		watcher: &TestWatcher{out: make(chan struct{})},
+	}
+	cfg := worker.PeriodicConfig{
+		Clock:       clock.WallClock,
+		NewTimer:    func(time.Duration) worker.PeriodicTimer { return timer },
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Second,
+	}
+	w, err := worker.NewPeriodicNotifyWorker(actor, cfg)
+	c.Assert(err, gc.IsNil)
+	defer w.Kill()
+
+	timer.c <- time.Now()
+	c.Check(timer.waitReset(c), gc.Equals, time.Millisecond)
+}
@@ -0,0 +1,109 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package worker
+
+import (
+	"fmt"
+
+	"launchpad.net/tomb"
+
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/watcher"
+)
+
+type stringsWorker struct {
+	tomb tomb.Tomb
+	// handler is what will handle when events are triggered
+	handler StringsWatchHandler
+}
+
+// StringsWorker encapsulates the state logic for a worker which is based
+// on a StringsWatcher. Like NotifyWorker, it does a bit of setup and then
+// spins waiting for the watcher to trigger or for us to be killed, and
+// then tears down cleanly, but it forwards the actual change set to the
+// handler instead of discarding it.
+type StringsWorker interface {
+	Wait() error
+	Kill()
+	// This is just Kill + Wait
+	Stop() error
+}
+
+// StringsWatchHandler implements the business logic that is triggered as
+// part of watching a StringsWatcher.
+type StringsWatchHandler interface {
+	// SetUp starts the handler, this should create the watcher we will be
+	// waiting on for more events. SetUp can return a Watcher even if
+	// there is an error, and StringsWorker will make sure to stop the
+	// Watcher.
+	SetUp() (state.StringsWatcher, error)
+	// TearDown cleans up any resources that are left around
+	TearDown()
+	// Handle is called when the watcher has indicated there are changes,
+	// do whatever work is necessary to process the changes that were
+	// passed in.
+	Handle(changes []string) error
+}
+
+// NewStringsWorker starts a new worker that loops around watching for
+// changes and passing them to the handler, exactly like NewNotifyWorker
+// but for a StringsWatcher, so the handler sees what actually changed
+// instead of just being told that something did.
+func NewStringsWorker(handler StringsWatchHandler) StringsWorker {
+	sw := &stringsWorker{
+		handler: handler,
+	}
+	go func() {
+		defer sw.tomb.Done()
+		sw.tomb.Kill(sw.loop())
+	}()
+	return sw
+}
+
+// Kill the loop with no-error
+func (sw *stringsWorker) Kill() {
+	sw.tomb.Kill(nil)
+}
+
+// Stop kills and waits for this to exit
+func (sw *stringsWorker) Stop() error {
+	sw.tomb.Kill(nil)
+	return sw.tomb.Wait()
+}
+
+// Wait for the looping to finish
+func (sw *stringsWorker) Wait() error {
+	return sw.tomb.Wait()
+}
+
+func (sw *stringsWorker) loop() error {
+	var w state.StringsWatcher
+	var err error
+	defer sw.handler.TearDown()
+	if w, err = sw.handler.SetUp(); err != nil {
+		if w != nil {
+			// We don't bother to propagate an error, because we
+			// already have an error
+			w.Stop()
+		}
+		return err
+	}
+	if w == nil {
+		return fmt.Errorf("SetUp returned a nil Watcher")
+	}
+	defer watcher.Stop(w, &sw.tomb)
+	for {
+		select {
+		case <-sw.tomb.Dying():
+			return tomb.ErrDying
+		case changes, ok := <-w.Changes():
+			if !ok {
+				return watcher.MustErr(w)
+			}
+			if err = sw.handler.Handle(changes); err != nil {
+				return err
+			}
+		}
+	}
+}
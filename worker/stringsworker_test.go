@@ -0,0 +1,194 @@
+// Copyright 2014 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package worker_test
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	gc "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state"
+	coretesting "launchpad.net/juju-core/testing"
+	jc "launchpad.net/juju-core/testing/checkers"
+	"launchpad.net/juju-core/worker"
+)
+
+type stringsWorkerSuite struct {
+	coretesting.LoggingSuite
+	worker worker.StringsWorker
+	actor  *StringsActionsHandler
+}
+
+var _ = gc.Suite(&stringsWorkerSuite{})
+
+func (s *stringsWorkerSuite) SetUpTest(c *gc.C) {
+	s.LoggingSuite.SetUpTest(c)
+	s.actor = &StringsActionsHandler{
+		actions: nil,
+		handled: make(chan []string),
+		watcher: &TestStringsWatcher{
+			out: make(chan []string),
+		},
+	}
+	s.worker = worker.NewStringsWorker(s.actor)
+}
+
+func (s *stringsWorkerSuite) TearDownTest(c *gc.C) {
+	s.stopWorker(c)
+	s.LoggingSuite.TearDownTest(c)
+}
+
+type StringsActionsHandler struct {
+	actions []string
+	mu      sync.Mutex
+	// handled is sent the changes passed to each Handle() call
+	handled      chan []string
+	setupError   error
+	handlerError error
+	watcher      *TestStringsWatcher
+}
+
+func (a *StringsActionsHandler) SetUp() (state.StringsWatcher, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.actions = append(a.actions, "setup")
+	if a.watcher == nil {
+		return nil, a.setupError
+	}
+	return a.watcher, a.setupError
+}
+
+func (a *StringsActionsHandler) TearDown() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.actions = append(a.actions, "teardown")
+}
+
+func (a *StringsActionsHandler) Handle(changes []string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.actions = append(a.actions, "handler")
+	if a.handled != nil {
+		a.handled <- changes
+	}
+	return a.handlerError
+}
+
+func (a *StringsActionsHandler) CheckActions(c *gc.C, actions ...string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	c.Check(a.actions, gc.DeepEquals, actions)
+}
+
+func (s *stringsWorkerSuite) stopWorker(c *gc.C) {
+	if s.worker == nil {
+		return
+	}
+	done := make(chan error)
+	go func() {
+		done <- s.worker.Stop()
+	}()
+	select {
+	case err := <-done:
+		c.Check(err, gc.IsNil)
+	case <-time.After(longWait):
+		c.Errorf("Failed to stop worker after %.3fs", longWait.Seconds())
+	}
+	s.actor = nil
+	s.worker = nil
+}
+
+type TestStringsWatcher struct {
+	mu        sync.Mutex
+	out       chan []string
+	stopped   bool
+	stopError error
+}
+
+func (tw *TestStringsWatcher) Changes() <-chan []string {
+	return tw.out
+}
+
+func (tw *TestStringsWatcher) Err() error {
+	return nil
+}
+
+func (tw *TestStringsWatcher) Stop() error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.stopped = true
+	return tw.stopError
+}
+
+func (tw *TestStringsWatcher) TriggerChange(c *gc.C, changes []string) {
+	select {
+	case tw.out <- changes:
+	case <-time.After(longWait):
+		c.Errorf("Timed out triggering change after %.3fs", longWait.Seconds())
+	}
+}
+
+func WaitForStringsHandled(c *gc.C, handled chan []string) []string {
+	select {
+	case changes := <-handled:
+		return changes
+	case <-time.After(longWait):
+		c.Errorf("handled failed to signal after %.3fs", longWait.Seconds())
+	}
+	return nil
+}
+
+func (s *stringsWorkerSuite) TestKill(c *gc.C) {
+	s.worker.Kill()
+	err := s.worker.Wait()
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *stringsWorkerSuite) TestCallSetUpAndTearDown(c *gc.C) {
+	s.actor.CheckActions(c, "setup")
+	s.worker.Kill()
+	err := s.worker.Wait()
+	c.Check(err, gc.IsNil)
+	s.actor.CheckActions(c, "setup", "teardown")
+	c.Check(s.actor.watcher.stopped, jc.IsTrue)
+}
+
+func (s *stringsWorkerSuite) TestChangesPassedToHandler(c *gc.C) {
+	s.actor.CheckActions(c, "setup")
+	s.actor.watcher.TriggerChange(c, []string{"foo", "bar"})
+	changes := WaitForStringsHandled(c, s.actor.handled)
+	c.Check(changes, gc.DeepEquals, []string{"foo", "bar"})
+	s.actor.CheckActions(c, "setup", "handler")
+	c.Assert(s.worker.Stop(), gc.IsNil)
+	s.actor.CheckActions(c, "setup", "handler", "teardown")
+}
+
+func (s *stringsWorkerSuite) TestSetupNilWatcherStopsWithTearDown(c *gc.C) {
+	s.stopWorker(c)
+	actor := &StringsActionsHandler{watcher: nil}
+	w := worker.NewStringsWorker(actor)
+	err := w.Stop()
+	c.Check(err, gc.ErrorMatches, "SetUp returned a nil Watcher")
+	actor.CheckActions(c, "setup", "teardown")
+}
+
+func (s *stringsWorkerSuite) TestHandleErrorStopsWorkerAndWatcher(c *gc.C) {
+	s.stopWorker(c)
+	actor := &StringsActionsHandler{
+		handled:      make(chan []string),
+		handlerError: fmt.Errorf("my handling error"),
+		watcher: &TestStringsWatcher{
+			out: make(chan []string),
+		},
+	}
+	w := worker.NewStringsWorker(actor)
+	actor.watcher.TriggerChange(c, []string{"foo"})
+	WaitForStringsHandled(c, actor.handled)
+	err := w.Stop()
+	c.Check(err, gc.ErrorMatches, "my handling error")
+	actor.CheckActions(c, "setup", "handler", "teardown")
+	c.Check(actor.watcher.stopped, jc.IsTrue)
+}